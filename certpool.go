@@ -0,0 +1,159 @@
+package certreloader
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"log/slog"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// A CertPoolReloader holds an *x509.CertPool built from a PEM-encoded CA bundle file, reloading
+// it from disk on the same interval-based schedule as Reloader. It is meant to be paired with a
+// Reloader so that both the trusted verification roots and the presented certificate of an mTLS
+// tls.Config can be rotated without a process restart; see NewMTLSConfig.
+type CertPoolReloader struct {
+	pool           atomic.Pointer[x509.CertPool]
+	lastPoolReload atomic.Int64
+	reloadCounter  atomic.Uint64
+	reloadFailures atomic.Uint64
+	reloadInterval time.Duration
+
+	caFile string
+
+	logger *slog.Logger
+
+	// reloadMu serializes reload so that concurrent callers of GetCertPool (every handshake
+	// through NewMTLSConfig's GetConfigForClient) can't interleave their writes to pool and
+	// lastPoolReload, or double-count a single on-disk change.
+	reloadMu sync.Mutex
+}
+
+// NewCertPoolReloader creates a new CertPoolReloader from the PEM-encoded CA bundle at caFile.
+func NewCertPoolReloader(caFile string) (*CertPoolReloader, error) {
+	pool, err := loadCertPool(caFile)
+	if err != nil {
+		return nil, err
+	}
+
+	r := &CertPoolReloader{
+		reloadInterval: defaultReloadInterval,
+		caFile:         caFile,
+	}
+	r.pool.Store(pool)
+	r.lastPoolReload.Store(time.Now().UnixNano())
+
+	return r, nil
+}
+
+// WithReloadInterval sets minimal reload interval for checking a new CA bundle.
+func (r *CertPoolReloader) WithReloadInterval(duration time.Duration) *CertPoolReloader {
+	logDebug(r.logger, "set reload interval", "interval", duration)
+
+	r.reloadInterval = duration
+	return r
+}
+
+// WithLogger sets the structured logger used for diagnostic logging. If unset, or set to nil,
+// logging is disabled; operators should rely on ReloadFailuresTotal in that case.
+func (r *CertPoolReloader) WithLogger(logger *slog.Logger) *CertPoolReloader {
+	if logger != nil {
+		logger = logger.With("component", logPrefix)
+	}
+	r.logger = logger
+	return r
+}
+
+// GetCertPool returns the current *x509.CertPool, reloading it from caFile first if needed.
+// Reload failures are logged and the previously loaded pool is returned, mirroring
+// GetCertificateFunc's behavior.
+//
+// The function is thread-safe
+func (r *CertPoolReloader) GetCertPool() *x509.CertPool {
+	if r.shouldReload() {
+		logDebug(r.logger, "should reload CA pool, load new x509.CertPool now")
+
+		err := r.reload()
+		if err != nil {
+			r.reloadFailures.Add(1)
+			logWarn(r.logger, "could not reload CA pool", "error", err)
+		}
+	}
+
+	return r.pool.Load()
+}
+
+// ReloadCounter returns the number of times the CA pool has been reloaded successfully since the
+// CertPoolReloader was created. It's intended for tests and metrics that need to observe or wait
+// for reload events.
+func (r *CertPoolReloader) ReloadCounter() uint64 {
+	return r.reloadCounter.Load()
+}
+
+// ReloadFailuresTotal returns the number of reload attempts that have failed since the
+// CertPoolReloader was created.
+func (r *CertPoolReloader) ReloadFailuresTotal() uint64 {
+	return r.reloadFailures.Load()
+}
+
+// shouldReload returns if the stored (cached) CA pool should be reevaluated.
+func (r *CertPoolReloader) shouldReload() bool {
+	lastReload := time.Unix(0, r.lastPoolReload.Load())
+	return time.Now().After(lastReload.Add(r.reloadInterval))
+}
+
+// reload reevaluates the CA pool from caFile. Returns error if the file read or PEM parsing
+// failed. reload serializes itself via reloadMu so concurrent callers can't interleave their
+// writes to pool and lastPoolReload.
+func (r *CertPoolReloader) reload() error {
+	r.reloadMu.Lock()
+	defer r.reloadMu.Unlock()
+
+	pool, err := loadCertPool(r.caFile)
+	if err != nil {
+		return err
+	}
+
+	r.pool.Store(pool)
+	r.lastPoolReload.Store(time.Now().UnixNano())
+	r.reloadCounter.Add(1)
+	logDebug(r.logger, "CA pool reloaded", "at", time.Unix(0, r.lastPoolReload.Load()))
+	return nil
+}
+
+// loadCertPool reads and parses a PEM-encoded CA bundle from caFile into an *x509.CertPool.
+func loadCertPool(caFile string) (*x509.CertPool, error) {
+	pemBytes, err := os.ReadFile(caFile)
+	if err != nil {
+		return nil, fmt.Errorf("could not read CA bundle: %w", err)
+	}
+
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(pemBytes) {
+		return nil, fmt.Errorf("could not parse any certificates from %s", caFile)
+	}
+
+	return pool, nil
+}
+
+// NewMTLSConfig wires cr and pool into a *tls.Config suitable for a server that requires and
+// verifies client certificates. The server's own certificate is served through cr's
+// GetCertificateFunc, and GetConfigForClient is used to hand each incoming connection a fresh
+// ClientCAs pool from pool, so renewed server certificates and rotated CA bundles both take
+// effect without a process restart.
+func NewMTLSConfig(cr *Reloader, pool *CertPoolReloader) *tls.Config {
+	return &tls.Config{
+		GetCertificate: cr.GetCertificateFunc,
+		ClientAuth:     tls.RequireAndVerifyClientCert,
+		GetConfigForClient: func(_ *tls.ClientHelloInfo) (*tls.Config, error) {
+			return &tls.Config{
+				GetCertificate: cr.GetCertificateFunc,
+				ClientAuth:     tls.RequireAndVerifyClientCert,
+				ClientCAs:      pool.GetCertPool(),
+			}, nil
+		},
+	}
+}
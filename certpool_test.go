@@ -0,0 +1,88 @@
+package certreloader
+
+import (
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+// writeTestCAFile writes certPEM as a standalone CA bundle file under t.TempDir() and returns its
+// path.
+func writeTestCAFile(t *testing.T, certPEM []byte) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "ca.pem")
+	if err := os.WriteFile(path, certPEM, 0o600); err != nil {
+		t.Fatalf("could not write CA bundle: %v", err)
+	}
+	return path
+}
+
+// TestCertPoolReloaderConcurrentReloadsAreSerialized verifies that concurrent reload() calls (as
+// happen when every mTLS handshake races through GetCertPool) each complete independently and
+// ReloadCounter advances exactly once per call, with lastPoolReload always matching the pool
+// that's actually stored.
+func TestCertPoolReloaderConcurrentReloadsAreSerialized(t *testing.T) {
+	now := time.Now()
+	certPEM, _ := generateTestCert(t, now.Add(-time.Hour), now.Add(time.Hour))
+	caFile := writeTestCAFile(t, certPEM)
+
+	r, err := NewCertPoolReloader(caFile)
+	if err != nil {
+		t.Fatalf("NewCertPoolReloader: %v", err)
+	}
+
+	const goroutines = 20
+	var wg sync.WaitGroup
+	wg.Add(goroutines)
+	for i := 0; i < goroutines; i++ {
+		go func() {
+			defer wg.Done()
+			if err := r.reload(); err != nil {
+				t.Errorf("reload: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if got := r.ReloadCounter(); got != goroutines {
+		t.Fatalf("ReloadCounter = %d, want %d", got, goroutines)
+	}
+
+	if r.pool.Load() == nil {
+		t.Fatal("pool should be set after reload")
+	}
+
+	lastReload := time.Unix(0, r.lastPoolReload.Load())
+	if lastReload.After(time.Now()) || lastReload.Before(now) {
+		t.Fatalf("lastPoolReload %v outside expected range", lastReload)
+	}
+}
+
+// TestCertPoolReloaderGetCertPoolReloadsOnInterval verifies that GetCertPool reloads the pool
+// once the reload interval has elapsed, and serves the cached pool otherwise.
+func TestCertPoolReloaderGetCertPoolReloadsOnInterval(t *testing.T) {
+	now := time.Now()
+	certPEM, _ := generateTestCert(t, now.Add(-time.Hour), now.Add(time.Hour))
+	caFile := writeTestCAFile(t, certPEM)
+
+	r, err := NewCertPoolReloader(caFile)
+	if err != nil {
+		t.Fatalf("NewCertPoolReloader: %v", err)
+	}
+	r.WithReloadInterval(10 * time.Millisecond)
+
+	_ = r.GetCertPool()
+	if got := r.ReloadCounter(); got != 0 {
+		t.Fatalf("ReloadCounter = %d before the interval elapsed, want 0", got)
+	}
+
+	time.Sleep(20 * time.Millisecond)
+
+	_ = r.GetCertPool()
+	if got := r.ReloadCounter(); got != 1 {
+		t.Fatalf("ReloadCounter = %d after the interval elapsed, want 1", got)
+	}
+}
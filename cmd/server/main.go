@@ -3,7 +3,9 @@ package main
 import (
 	"crypto/tls"
 	"flag"
+	"log/slog"
 	"net/http"
+	"os"
 	"time"
 
 	"github.com/sinu5oid/certreloader"
@@ -23,7 +25,10 @@ func main() {
 		panic(err)
 	}
 
-	cr.WithReloadInterval(time.Duration(*renewal) * time.Second).SetVerbose(*verbose)
+	cr.WithReloadInterval(time.Duration(*renewal) * time.Second)
+	if *verbose {
+		cr.WithLogger(slog.New(slog.NewTextHandler(os.Stderr, nil)))
+	}
 
 	http.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
 		w.Write([]byte("Hello World"))
@@ -34,7 +39,7 @@ func main() {
 		Addr:    *address,
 		Handler: nil,
 		TLSConfig: &tls.Config{
-			GetCertificate: cr.GetCertificateFunc(),
+			GetCertificate: cr.GetCertificateFunc,
 		},
 	}
 
@@ -0,0 +1,146 @@
+package certreloader
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"log/slog"
+	"testing"
+	"time"
+)
+
+// TestReloaderHooksFireOnSuccess verifies that a successful reload runs OnReload and
+// OnReloadSuccess hooks, but not OnReloadError, and passes the old/new certificates through.
+func TestReloaderHooksFireOnSuccess(t *testing.T) {
+	now := time.Now()
+	certFile, keyFile := writeTestKeyPair(t, now.Add(-time.Hour), now.Add(time.Hour))
+
+	r, err := NewReloader(certFile, keyFile)
+	if err != nil {
+		t.Fatalf("NewReloader: %v", err)
+	}
+
+	var onReloadCalls, onSuccessCalls, onErrorCalls int
+	r.OnReload(func(old, new *tls.Certificate, err error) {
+		onReloadCalls++
+		if old == nil {
+			t.Error("OnReload should receive the previous certificate")
+		}
+		if new == nil {
+			t.Error("OnReload should receive the new certificate on success")
+		}
+		if err != nil {
+			t.Errorf("OnReload received unexpected error: %v", err)
+		}
+	})
+	r.OnReloadSuccess(func(cert *tls.Certificate) {
+		onSuccessCalls++
+		if cert == nil {
+			t.Error("OnReloadSuccess should receive the new certificate")
+		}
+	})
+	r.OnReloadError(func(err error) {
+		onErrorCalls++
+	})
+
+	if err := r.reload(context.Background()); err != nil {
+		t.Fatalf("reload: %v", err)
+	}
+
+	if onReloadCalls != 1 {
+		t.Errorf("OnReload called %d times, want 1", onReloadCalls)
+	}
+	if onSuccessCalls != 1 {
+		t.Errorf("OnReloadSuccess called %d times, want 1", onSuccessCalls)
+	}
+	if onErrorCalls != 0 {
+		t.Errorf("OnReloadError called %d times, want 0", onErrorCalls)
+	}
+}
+
+// TestReloaderHooksFireOnFailure verifies that a failed reload runs OnReload and OnReloadError
+// hooks, but not OnReloadSuccess, and that ReloadFailuresTotal advances without ReloadsTotal.
+func TestReloaderHooksFireOnFailure(t *testing.T) {
+	now := time.Now()
+	certFile, keyFile := writeTestKeyPair(t, now.Add(-time.Hour), now.Add(time.Hour))
+
+	// Build a Reloader that loads successfully once, then fails on the next reload.
+	goodCert, loadErr := NewFileSource(certFile, keyFile).Load(context.Background())
+	if loadErr != nil {
+		t.Fatalf("could not load keypair: %v", loadErr)
+	}
+	failing := &flakySource{cert: goodCert}
+	rl, err := NewReloaderFromSource(failing)
+	if err != nil {
+		t.Fatalf("NewReloaderFromSource: %v", err)
+	}
+	failing.fail = true
+
+	var onReloadCalls, onSuccessCalls, onErrorCalls int
+	rl.OnReload(func(old, new *tls.Certificate, err error) {
+		onReloadCalls++
+		if err == nil {
+			t.Error("OnReload should receive the error on failure")
+		}
+	})
+	rl.OnReloadSuccess(func(cert *tls.Certificate) {
+		onSuccessCalls++
+	})
+	rl.OnReloadError(func(err error) {
+		onErrorCalls++
+	})
+
+	if err := rl.reload(context.Background()); err == nil {
+		t.Fatal("expected reload to fail")
+	}
+
+	if onReloadCalls != 1 {
+		t.Errorf("OnReload called %d times, want 1", onReloadCalls)
+	}
+	if onSuccessCalls != 0 {
+		t.Errorf("OnReloadSuccess called %d times, want 0", onSuccessCalls)
+	}
+	if onErrorCalls != 1 {
+		t.Errorf("OnReloadError called %d times, want 1", onErrorCalls)
+	}
+	if got := rl.ReloadFailuresTotal(); got != 1 {
+		t.Errorf("ReloadFailuresTotal() = %d, want 1", got)
+	}
+	if got := rl.ReloadsTotal(); got != 0 {
+		t.Errorf("ReloadsTotal() = %d, want 0", got)
+	}
+}
+
+// TestReloaderWithNilLoggerDoesNotPanic verifies that logging helpers are no-ops when no logger
+// has been configured, and that WithLogger(nil) can be used to explicitly disable logging.
+func TestReloaderWithNilLoggerDoesNotPanic(t *testing.T) {
+	now := time.Now()
+	certFile, keyFile := writeTestKeyPair(t, now.Add(-time.Hour), now.Add(time.Hour))
+
+	r, err := NewReloader(certFile, keyFile)
+	if err != nil {
+		t.Fatalf("NewReloader: %v", err)
+	}
+
+	r.WithLogger(slog.Default())
+	r.WithLogger(nil)
+
+	if err := r.reload(context.Background()); err != nil {
+		t.Fatalf("reload: %v", err)
+	}
+}
+
+// flakySource loads cert until fail is set, after which it returns an error.
+type flakySource struct {
+	cert tls.Certificate
+	fail bool
+}
+
+func (s *flakySource) Load(_ context.Context) (tls.Certificate, error) {
+	if s.fail {
+		return tls.Certificate{}, errFlaky
+	}
+	return s.cert, nil
+}
+
+var errFlaky = errors.New("flaky source failed")
@@ -7,10 +7,14 @@
 package certreloader
 
 import (
+	"context"
 	"crypto/tls"
+	"crypto/x509"
 	"fmt"
-	"log"
+	"log/slog"
+	mrand "math/rand"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
@@ -19,55 +23,335 @@ const logPrefix = "certreloader"
 // defaultReloadInterval is 24 hours, a reasonable caching period, assuming certbot reloads certs at least twice a day
 const defaultReloadInterval = time.Hour * 24
 
+// fsNotifyDebounce collapses bursts of filesystem events into a single reload. Certbot (and
+// similar tools) typically touch several files in quick succession during a renewal, so a
+// single event shouldn't trigger a reload immediately.
+const fsNotifyDebounce = 200 * time.Millisecond
+
+// defaultRenewalThreshold is the fraction of a certificate's validity window that must elapse
+// before Start attempts a proactive renewal, matching common ACME client behavior.
+const defaultRenewalThreshold = 2.0 / 3.0
+
+// renewalBackoff is how long Start's background goroutine waits before retrying when it has no
+// usable leaf certificate to schedule against, or when the current one is already expired.
+const renewalBackoff = time.Minute
+
+// logDebug and logWarn are shared by Reloader and CertPoolReloader, both of which treat a nil
+// logger as "logging disabled".
+
+func logDebug(logger *slog.Logger, msg string, args ...any) {
+	if logger == nil {
+		return
+	}
+	logger.Debug(msg, args...)
+}
+
+func logWarn(logger *slog.Logger, msg string, args ...any) {
+	if logger == nil {
+		return
+	}
+	logger.Warn(msg, args...)
+}
+
 // A Reloader holds tls.Certificate with inner cert reload function implementation.
-// It is assumed that certFile and keyFile last for entire process lifetime and won't produce a file reading error.
+// It is assumed that source last for entire process lifetime and won't produce a read error.
 type Reloader struct {
-	m              *sync.RWMutex
-	cert           *tls.Certificate
-	lastCertReload time.Time
+	source Source
+
+	cert           atomic.Pointer[tls.Certificate]
+	leaf           atomic.Pointer[x509.Certificate] // parsed cert.Certificate[0], used to schedule proactive renewal
+	lastCertReload atomic.Int64                     // UnixNano, kept alongside cert so GetCertificateFunc stays lock-free
+	reloadCounter  atomic.Uint64                    // number of successful reloads, exposed via ReloadCounter/ReloadsTotal
+	reloadFailures atomic.Uint64                    // number of failed reload attempts, exposed via ReloadFailuresTotal
 	reloadInterval time.Duration
 
-	certFile string
-	keyFile  string
+	renewalThreshold float64
+	renewalJitter    time.Duration
 
-	verbose bool
+	logger *slog.Logger
+
+	onReload        []func(old, new *tls.Certificate, err error)
+	onReloadSuccess []func(cert *tls.Certificate)
+	onReloadError   []func(err error)
+
+	// reloadMu serializes reload so that GetCertificateFunc's interval check, the fsnotify
+	// watcher, and the renewal loop can never interleave their writes to cert/leaf/lastCertReload,
+	// and so a single on-disk change is never counted or hooked more than once.
+	reloadMu sync.Mutex
+
+	watcherWg sync.WaitGroup
+	closeOnce sync.Once
+	closeCh   chan struct{}
 }
 
-// NewReloader creates new Reloader struct with provided certFile, keyFile locations.
-func NewReloader(certFile string, keyFile string) (*Reloader, error) {
-	cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+// NewReloaderFromSource creates a new Reloader that loads and reloads its certificate through
+// source, rather than directly from a certFile, keyFile pair. This is the extension point for
+// certificate backends other than the filesystem, such as HashiCorp Vault PKI, the SPIRE Workload
+// API, or Kubernetes secrets: implement Source (and, for push-based reloads, WatchableSource) and
+// pass it here.
+func NewReloaderFromSource(source Source) (*Reloader, error) {
+	cert, err := source.Load(context.Background())
 	if err != nil {
-		return nil, fmt.Errorf("could not load keypair: %w", err)
+		return nil, fmt.Errorf("could not load certificate from source: %w", err)
 	}
 
-	return &Reloader{
-		m:              &sync.RWMutex{},
-		cert:           &cert,
-		lastCertReload: time.Now(),
-		reloadInterval: defaultReloadInterval,
-		certFile:       certFile,
-		keyFile:        keyFile,
-		verbose:        false,
-	}, nil
+	r := &Reloader{
+		source:           source,
+		reloadInterval:   defaultReloadInterval,
+		renewalThreshold: defaultRenewalThreshold,
+		closeCh:          make(chan struct{}),
+	}
+	if err := r.storeCert(cert); err != nil {
+		return nil, err
+	}
+
+	return r, nil
+}
+
+// NewReloader creates a new Reloader struct with provided certFile, keyFile locations. It is a
+// thin wrapper around NewReloaderFromSource using a FileSource, kept for backwards compatibility.
+func NewReloader(certFile string, keyFile string) (*Reloader, error) {
+	return NewReloaderFromSource(NewFileSource(certFile, keyFile))
 }
 
 // WithReloadInterval sets minimal reload interval for checking new certificate.
 func (r *Reloader) WithReloadInterval(duration time.Duration) *Reloader {
-	r.m.Lock()
-	defer r.m.Unlock()
-
-	r.logf("set reload interval to %v", duration)
+	logDebug(r.logger, "set reload interval", "interval", duration)
 
 	r.reloadInterval = duration
 	return r
 }
 
-// SetVerbose enables verbose logging for debugging purposes
-func (r *Reloader) SetVerbose(verbose bool) *Reloader {
-	r.verbose = verbose
+// Watch consumes reload signals from a WatchableSource, triggering an immediate reload whenever
+// the source pushes one instead of waiting for the next interval-based check. It returns an error
+// if source isn't a WatchableSource.
+//
+// The interval-based check already performed by GetCertificateFunc keeps running as a fallback
+// for sources whose push notifications aren't delivered reliably.
+//
+// The watcher goroutine stops when ctx is cancelled or Close is called.
+func (r *Reloader) Watch(ctx context.Context) (*Reloader, error) {
+	watchable, ok := r.source.(WatchableSource)
+	if !ok {
+		return nil, fmt.Errorf("Watch requires a WatchableSource, got %T", r.source)
+	}
+
+	signals, err := watchable.Watch(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("could not start watching source: %w", err)
+	}
+
+	r.watcherWg.Add(1)
+	go r.watchSignals(ctx, signals)
+
+	return r, nil
+}
+
+// WithFSNotify starts an fsnotify-based watcher on the parent directories of the FileSource's
+// certFile and keyFile, and triggers an immediate reload whenever either file receives a WRITE,
+// CREATE, or RENAME event, instead of waiting for the next interval-based check. Watching the
+// parent directory rather than the file itself is required because certbot (and similar tools)
+// replace a certificate by renaming a new file over the old one, which invalidates a watch held
+// directly on the original file.
+//
+// WithFSNotify requires the Reloader to be backed by a FileSource; it returns an error for any
+// other Source. Source implementations that can watch their own backend for changes should
+// implement WatchableSource and use Watch instead.
+//
+// The watcher goroutine stops when ctx is cancelled or Close is called.
+func (r *Reloader) WithFSNotify(ctx context.Context) (*Reloader, error) {
+	if _, ok := r.source.(*FileSource); !ok {
+		return nil, fmt.Errorf("WithFSNotify requires a *FileSource, got %T", r.source)
+	}
+
+	return r.Watch(ctx)
+}
+
+// watchSignals consumes reload signals pushed by a WatchableSource and triggers a reload for
+// each one, until ctx is cancelled, Close is called, or the channel is closed.
+func (r *Reloader) watchSignals(ctx context.Context, signals <-chan struct{}) {
+	defer r.watcherWg.Done()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-r.closeCh:
+			return
+		case _, ok := <-signals:
+			if !ok {
+				logWarn(r.logger, "watch channel closed, stopping watch-driven reloads")
+				return
+			}
+			if err := r.reload(ctx); err != nil {
+				logWarn(r.logger, "could not reload certificate after watch signal", "error", err)
+			}
+		}
+	}
+}
+
+// Close stops the background goroutines started by Watch, WithFSNotify, and Start, if any, and
+// closes the source if it implements io.Closer. It is safe to call more than once, and safe to
+// call even if none of Watch, WithFSNotify, or Start were used.
+func (r *Reloader) Close() error {
+	r.closeOnce.Do(func() {
+		close(r.closeCh)
+	})
+
+	var err error
+	if closer, ok := r.source.(interface{ Close() error }); ok {
+		err = closer.Close()
+	}
+	r.watcherWg.Wait()
+	return err
+}
+
+// WithRenewalThreshold sets the fraction of a certificate's validity window that must elapse
+// before Start's background goroutine attempts a proactive renewal. fraction should be in (0, 1];
+// the default is 2/3, matching common ACME client behavior.
+func (r *Reloader) WithRenewalThreshold(fraction float64) *Reloader {
+	logDebug(r.logger, "set renewal threshold", "threshold", fraction)
+
+	r.renewalThreshold = fraction
+	return r
+}
+
+// WithRenewalJitter sets the maximum random jitter subtracted from each proactive renewal wait
+// computed by Start, so that a fleet of processes sharing the same certificate doesn't all
+// attempt renewal at the exact same instant.
+func (r *Reloader) WithRenewalJitter(d time.Duration) *Reloader {
+	logDebug(r.logger, "set renewal jitter", "jitter", d)
+
+	r.renewalJitter = d
 	return r
 }
 
+// Start begins a background goroutine that proactively reloads the certificate ahead of its
+// expiration, based on the leaf certificate's NotAfter rather than waiting on a fixed interval.
+// After each reload attempt, the next check is scheduled for renewalThreshold of the way through
+// the current certificate's validity window, minus up to renewalJitter of random jitter. If the
+// certificate on disk hasn't changed, the goroutine simply reschedules against the same NotAfter;
+// if the certificate is already expired, it retries after a short backoff.
+//
+// This lets the module cooperate with short-lived certificates (Let's Encrypt 90d, step-ca 24h,
+// SPIFFE hourly) without operators having to hand-tune reloadInterval.
+//
+// The goroutine stops when ctx is cancelled or Close is called.
+func (r *Reloader) Start(ctx context.Context) {
+	r.watcherWg.Add(1)
+	go r.renewalLoop(ctx)
+}
+
+// renewalLoop sleeps until the next proactive renewal check and calls reload, repeating until ctx
+// is cancelled or Close is called.
+func (r *Reloader) renewalLoop(ctx context.Context) {
+	defer r.watcherWg.Done()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-r.closeCh:
+			return
+		case <-time.After(r.nextRenewalCheck()):
+		}
+
+		if err := r.reload(ctx); err != nil {
+			logWarn(r.logger, "could not reload certificate during proactive renewal check", "error", err)
+		}
+	}
+}
+
+// nextRenewalCheck computes how long to wait before the next proactive renewal check, based on
+// the current leaf certificate's validity window.
+func (r *Reloader) nextRenewalCheck() time.Duration {
+	leaf := r.leaf.Load()
+	if leaf == nil {
+		return renewalBackoff
+	}
+
+	now := time.Now()
+	if now.After(leaf.NotAfter) {
+		return renewalBackoff
+	}
+
+	validity := leaf.NotAfter.Sub(leaf.NotBefore)
+	renewAt := leaf.NotBefore.Add(time.Duration(float64(validity) * r.renewalThreshold))
+
+	wait := renewAt.Sub(now)
+	if r.renewalJitter > 0 {
+		wait -= time.Duration(mrand.Int63n(int64(r.renewalJitter)))
+	}
+	if wait <= 0 {
+		return renewalBackoff
+	}
+
+	return wait
+}
+
+// WithLogger sets the structured logger used for diagnostic logging (set up, reload attempts,
+// fsnotify and renewal goroutine activity). If unset, or set to nil, logging is disabled, so the
+// failure signal for a broken reload comes down to ReloadFailuresTotal, OnReloadError, or the
+// error returned by whichever constructor or reload path is in use.
+func (r *Reloader) WithLogger(logger *slog.Logger) *Reloader {
+	if logger != nil {
+		logger = logger.With("component", logPrefix)
+	}
+	r.logger = logger
+	return r
+}
+
+// OnReload registers a hook invoked after every reload attempt, successful or not. old is the
+// certificate that was in use before the attempt, new is the freshly loaded certificate (nil on
+// failure), and err is the error returned by the attempt, if any. Hooks run synchronously on
+// whichever goroutine performed the reload (a GetCertificateFunc/GetClientCertificateFunc caller,
+// the fsnotify watcher, or the Start renewal loop), so keep them fast.
+func (r *Reloader) OnReload(hook func(old, new *tls.Certificate, err error)) *Reloader {
+	r.onReload = append(r.onReload, hook)
+	return r
+}
+
+// OnReloadSuccess registers a hook invoked with the newly loaded certificate after every
+// successful reload.
+func (r *Reloader) OnReloadSuccess(hook func(cert *tls.Certificate)) *Reloader {
+	r.onReloadSuccess = append(r.onReloadSuccess, hook)
+	return r
+}
+
+// OnReloadError registers a hook invoked with the encountered error after every failed reload
+// attempt.
+func (r *Reloader) OnReloadError(hook func(err error)) *Reloader {
+	r.onReloadError = append(r.onReloadError, hook)
+	return r
+}
+
+// ReloadsTotal returns the number of successful reloads since the Reloader was created. It's an
+// alias of ReloadCounter, named to match ReloadFailuresTotal for Prometheus-style metric wiring.
+func (r *Reloader) ReloadsTotal() uint64 {
+	return r.reloadCounter.Load()
+}
+
+// ReloadFailuresTotal returns the number of reload attempts that have failed since the Reloader
+// was created. Operators should alert when this counter advances without a matching increase in
+// ReloadsTotal, since that signals the certificate on disk can no longer be loaded.
+func (r *Reloader) ReloadFailuresTotal() uint64 {
+	return r.reloadFailures.Load()
+}
+
+// LastReloadTime returns the time of the most recent successful reload.
+func (r *Reloader) LastReloadTime() time.Time {
+	return time.Unix(0, r.lastCertReload.Load())
+}
+
+// LeafNotAfter returns the NotAfter of the currently loaded leaf certificate.
+func (r *Reloader) LeafNotAfter() time.Time {
+	leaf := r.leaf.Load()
+	if leaf == nil {
+		return time.Time{}
+	}
+	return leaf.NotAfter
+}
+
 // GetCertificateFunc wraps an implementations for [tls.TLSConfig] GetCertificate function.
 // Reloads the certificate if needed before returning.
 // Fails if reload function returns an error.
@@ -75,47 +359,123 @@ func (r *Reloader) SetVerbose(verbose bool) *Reloader {
 // The function is thread-safe
 func (r *Reloader) GetCertificateFunc(_ *tls.ClientHelloInfo) (*tls.Certificate, error) {
 	if r.shouldReload() {
-		r.logf("should reload certificate, load new tls.Certificate now")
+		logDebug(r.logger, "should reload certificate, load new tls.Certificate now")
 
-		err := r.reload()
+		err := r.reload(context.Background())
 		if err != nil {
-			r.logf("could not reload certificate: %v", err)
+			logWarn(r.logger, "could not reload certificate", "error", err)
 		}
 	}
 
-	r.m.RLock()
-	defer r.m.RUnlock()
-	return r.cert, nil
+	return r.cert.Load(), nil
+}
+
+// GetClientCertificateFunc wraps an implementation for [tls.Config] GetClientCertificate
+// function, so a Reloader can also serve outbound TLS clients (gRPC, HTTP) that need to present
+// a certificate for mTLS. Reloads the certificate if needed before returning, mirroring
+// GetCertificateFunc.
+//
+// The function is thread-safe
+func (r *Reloader) GetClientCertificateFunc(_ *tls.CertificateRequestInfo) (*tls.Certificate, error) {
+	if r.shouldReload() {
+		logDebug(r.logger, "should reload certificate, load new tls.Certificate now")
+
+		err := r.reload(context.Background())
+		if err != nil {
+			logWarn(r.logger, "could not reload certificate", "error", err)
+		}
+	}
+
+	return r.cert.Load(), nil
+}
+
+// ReloadCounter returns the number of times the certificate has been reloaded successfully since
+// the Reloader was created. It's intended for tests and metrics that need to observe or wait for
+// reload events.
+func (r *Reloader) ReloadCounter() uint64 {
+	return r.reloadCounter.Load()
 }
 
 // shouldReload returns if the stored (cached) certificate should be reevaluated.
 func (r *Reloader) shouldReload() bool {
-	r.m.RLock()
-	defer r.m.RUnlock()
+	lastReload := time.Unix(0, r.lastCertReload.Load())
+	return time.Now().After(lastReload.Add(r.reloadInterval))
+}
 
-	return time.Now().After(r.lastCertReload.Add(r.reloadInterval))
+// reload reevaluates the certificate through source. Returns error if the source or the resulting
+// tls.Certificate construct failed. Runs any hooks registered through OnReload, OnReloadSuccess,
+// and OnReloadError before returning.
+//
+// reload serializes the load-and-store part of itself via reloadMu: GetCertificateFunc's interval
+// check, the fsnotify watcher, and the renewal loop can all call it concurrently, and without
+// serialization their stores to cert/leaf/lastCertReload could interleave, leaving cert and leaf
+// describing two different certificates. Hooks run after reloadMu is released, so a hook that
+// calls back into reload (or GetCertificateFunc/GetClientCertificateFunc) doesn't deadlock on the
+// non-reentrant mutex.
+func (r *Reloader) reload(ctx context.Context) error {
+	old, newCert, err := r.loadAndStore(ctx)
+	r.runReloadHooks(old, newCert, err)
+	return err
 }
 
-// reload reevaluates the certificate by certFile / keyFile pair. Returns error if file read or tls.Certificate
-// construct failed.
-func (r *Reloader) reload() error {
-	cert, err := tls.LoadX509KeyPair(r.certFile, r.keyFile)
+// loadAndStore does the locked part of reload: loading the certificate from source and storing
+// it. It returns the previously stored certificate, the newly stored certificate (nil on
+// failure), and any error encountered.
+func (r *Reloader) loadAndStore(ctx context.Context) (old, newCert *tls.Certificate, err error) {
+	r.reloadMu.Lock()
+	defer r.reloadMu.Unlock()
+
+	old = r.cert.Load()
+
+	cert, err := r.source.Load(ctx)
 	if err != nil {
-		return fmt.Errorf("could not load keypair: %w", err)
+		return old, nil, fmt.Errorf("could not load certificate from source: %w", err)
 	}
 
-	r.m.Lock()
-	defer r.m.Unlock()
-	r.cert = &cert
-	r.lastCertReload = time.Now()
-	r.logf("certificate reloaded at %s", r.lastCertReload)
-	return nil
+	if err := r.storeCert(cert); err != nil {
+		return old, nil, err
+	}
+	r.reloadCounter.Add(1)
+
+	newCert = r.cert.Load()
+	logDebug(r.logger, "certificate reloaded", "at", time.Unix(0, r.lastCertReload.Load()))
+	return old, newCert, nil
 }
 
-func (r *Reloader) logf(format string, v ...interface{}) {
-	if !r.verbose {
+// runReloadHooks invokes the hooks registered through OnReload, OnReloadSuccess, and
+// OnReloadError, and keeps reloadFailures in sync.
+func (r *Reloader) runReloadHooks(old, new *tls.Certificate, err error) {
+	for _, hook := range r.onReload {
+		hook(old, new, err)
+	}
+
+	if err != nil {
+		r.reloadFailures.Add(1)
+		for _, hook := range r.onReloadError {
+			hook(err)
+		}
 		return
 	}
 
-	log.Printf(fmt.Sprintf("%s %s", logPrefix, format), v...)
+	for _, hook := range r.onReloadSuccess {
+		hook(new)
+	}
+}
+
+// storeCert stores cert as the current certificate and parses its leaf, which Start uses to
+// schedule proactive renewal checks.
+func (r *Reloader) storeCert(cert tls.Certificate) error {
+	if len(cert.Certificate) == 0 {
+		return fmt.Errorf("certificate from source has no DER-encoded certificates")
+	}
+
+	leaf, err := x509.ParseCertificate(cert.Certificate[0])
+	if err != nil {
+		return fmt.Errorf("could not parse leaf certificate: %w", err)
+	}
+
+	r.cert.Store(&cert)
+	r.leaf.Store(leaf)
+	r.lastCertReload.Store(time.Now().UnixNano())
+	return nil
 }
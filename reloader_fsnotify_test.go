@@ -0,0 +1,94 @@
+package certreloader
+
+import (
+	"context"
+	"crypto/x509"
+	"os"
+	"sync"
+	"testing"
+	"time"
+)
+
+// TestReloaderWithFSNotifyReloadsOnWrite verifies that rewriting the certificate and key files
+// triggers a reload via the fsnotify watcher, without waiting for the interval-based check.
+func TestReloaderWithFSNotifyReloadsOnWrite(t *testing.T) {
+	now := time.Now()
+	certFile, keyFile := writeTestKeyPair(t, now.Add(-time.Hour), now.Add(time.Hour))
+
+	r, err := NewReloader(certFile, keyFile)
+	if err != nil {
+		t.Fatalf("NewReloader: %v", err)
+	}
+	r.WithReloadInterval(time.Hour)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	if _, err := r.WithFSNotify(ctx); err != nil {
+		t.Fatalf("WithFSNotify: %v", err)
+	}
+	defer r.Close()
+
+	before := r.ReloadCounter()
+
+	certPEM, keyPEM := generateTestCert(t, now, now.Add(2*time.Hour))
+	if err := os.WriteFile(certFile, certPEM, 0o600); err != nil {
+		t.Fatalf("could not rewrite cert file: %v", err)
+	}
+	if err := os.WriteFile(keyFile, keyPEM, 0o600); err != nil {
+		t.Fatalf("could not rewrite key file: %v", err)
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for r.ReloadCounter() == before {
+		if time.Now().After(deadline) {
+			t.Fatalf("reload did not happen within the deadline, counter still %d", r.ReloadCounter())
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+// TestReloaderConcurrentReloadsAreSerialized verifies that concurrent reload attempts (as would
+// happen when the fsnotify watcher and GetCertificateFunc's interval check race) never produce a
+// torn write where cert and leaf describe different certificates, and that each completed reload
+// increments ReloadCounter exactly once.
+func TestReloaderConcurrentReloadsAreSerialized(t *testing.T) {
+	now := time.Now()
+	certFile, keyFile := writeTestKeyPair(t, now.Add(-time.Hour), now.Add(time.Hour))
+
+	r, err := NewReloader(certFile, keyFile)
+	if err != nil {
+		t.Fatalf("NewReloader: %v", err)
+	}
+
+	const goroutines = 20
+	var wg sync.WaitGroup
+	wg.Add(goroutines)
+	for i := 0; i < goroutines; i++ {
+		go func() {
+			defer wg.Done()
+			if err := r.reload(context.Background()); err != nil {
+				t.Errorf("reload: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if got := r.ReloadCounter(); got != goroutines {
+		t.Fatalf("ReloadCounter = %d, want %d", got, goroutines)
+	}
+
+	cert := r.cert.Load()
+	leaf := r.leaf.Load()
+	if cert == nil || leaf == nil {
+		t.Fatalf("cert and leaf should both be set, got cert=%v leaf=%v", cert, leaf)
+	}
+
+	wantLeaf, err := x509.ParseCertificate(cert.Certificate[0])
+	if err != nil {
+		t.Fatalf("could not parse leaf of stored certificate: %v", err)
+	}
+	if !leaf.Equal(wantLeaf) {
+		t.Fatalf("leaf does not match the parsed leaf of the stored certificate")
+	}
+}
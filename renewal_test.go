@@ -0,0 +1,88 @@
+package certreloader
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+// TestNextRenewalCheckNoLeaf verifies nextRenewalCheck backs off when no leaf certificate has
+// been loaded yet.
+func TestNextRenewalCheckNoLeaf(t *testing.T) {
+	r := &Reloader{renewalThreshold: defaultRenewalThreshold}
+
+	if got := r.nextRenewalCheck(); got != renewalBackoff {
+		t.Fatalf("nextRenewalCheck() = %v, want %v", got, renewalBackoff)
+	}
+}
+
+// TestNextRenewalCheckExpiredLeaf verifies nextRenewalCheck backs off when the current leaf
+// certificate has already expired, rather than computing a negative or zero wait.
+func TestNextRenewalCheckExpiredLeaf(t *testing.T) {
+	now := time.Now()
+	certFile, keyFile := writeTestKeyPair(t, now.Add(-2*time.Hour), now.Add(-time.Hour))
+
+	r, err := NewReloader(certFile, keyFile)
+	if err != nil {
+		t.Fatalf("NewReloader: %v", err)
+	}
+
+	if got := r.nextRenewalCheck(); got != renewalBackoff {
+		t.Fatalf("nextRenewalCheck() = %v, want %v", got, renewalBackoff)
+	}
+}
+
+// TestNextRenewalCheckScheduledAtThreshold verifies nextRenewalCheck schedules the next check at
+// renewalThreshold of the way through the certificate's validity window, measured from NotBefore.
+func TestNextRenewalCheckScheduledAtThreshold(t *testing.T) {
+	now := time.Now()
+	notBefore := now.Add(-time.Hour)
+	notAfter := now.Add(3 * time.Hour)
+	certFile, keyFile := writeTestKeyPair(t, notBefore, notAfter)
+
+	r, err := NewReloader(certFile, keyFile)
+	if err != nil {
+		t.Fatalf("NewReloader: %v", err)
+	}
+	r.WithRenewalThreshold(0.5)
+
+	validity := notAfter.Sub(notBefore)
+	wantRenewAt := notBefore.Add(time.Duration(float64(validity) * 0.5))
+	wantWait := wantRenewAt.Sub(now)
+
+	got := r.nextRenewalCheck()
+	if diff := got - wantWait; diff < -time.Second || diff > time.Second {
+		t.Fatalf("nextRenewalCheck() = %v, want approximately %v", got, wantWait)
+	}
+}
+
+// TestStartReloadsAheadOfExpiration verifies Start's background goroutine proactively reloads the
+// certificate once renewalThreshold of its validity window has elapsed, without waiting for
+// reloadInterval.
+func TestStartReloadsAheadOfExpiration(t *testing.T) {
+	now := time.Now()
+	notBefore := now.Add(-1 * time.Second)
+	notAfter := now.Add(3 * time.Second)
+	certFile, keyFile := writeTestKeyPair(t, notBefore, notAfter)
+
+	r, err := NewReloader(certFile, keyFile)
+	if err != nil {
+		t.Fatalf("NewReloader: %v", err)
+	}
+	r.WithReloadInterval(time.Hour)
+	r.WithRenewalThreshold(0.5)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	r.Start(ctx)
+	defer r.Close()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for r.ReloadCounter() == 0 {
+		if time.Now().After(deadline) {
+			t.Fatalf("Start did not trigger a proactive renewal check within the deadline")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
@@ -0,0 +1,222 @@
+package certreloader
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// A Source supplies a Reloader with a certificate on demand. Implementations are free to read
+// from disk, a secrets manager, or an in-memory value; Reloader only requires that Load be safe
+// to call repeatedly and return the current certificate each time.
+type Source interface {
+	// Load returns the current certificate. It is called once at construction time and again on
+	// every reload check.
+	Load(ctx context.Context) (tls.Certificate, error)
+}
+
+// A WatchableSource is a Source that can additionally push reload signals instead of making
+// Reloader wait for its next poll. The returned channel should receive a value whenever the
+// source believes a new certificate may be available; Reloader reacts by reloading immediately,
+// short-circuiting the interval-based check.
+type WatchableSource interface {
+	Source
+	Watch(ctx context.Context) (<-chan struct{}, error)
+}
+
+// A FileSource loads a certificate and key from a pair of PEM files on disk, using
+// tls.LoadX509KeyPair. It is the Source NewReloader wraps, and implements WatchableSource using
+// fsnotify.
+type FileSource struct {
+	CertFile string
+	KeyFile  string
+
+	logger  *slog.Logger
+	watcher *fsnotify.Watcher
+}
+
+// NewFileSource creates a FileSource for the given certFile, keyFile pair.
+func NewFileSource(certFile, keyFile string) *FileSource {
+	return &FileSource{CertFile: certFile, KeyFile: keyFile}
+}
+
+// WithLogger sets the structured logger used to report fsnotify watcher errors from Watch. If
+// unset, or set to nil, logging is disabled.
+func (s *FileSource) WithLogger(logger *slog.Logger) *FileSource {
+	if logger != nil {
+		logger = logger.With("component", logPrefix)
+	}
+	s.logger = logger
+	return s
+}
+
+// Load implements Source.
+func (s *FileSource) Load(_ context.Context) (tls.Certificate, error) {
+	cert, err := tls.LoadX509KeyPair(s.CertFile, s.KeyFile)
+	if err != nil {
+		return tls.Certificate{}, fmt.Errorf("could not load keypair: %w", err)
+	}
+
+	return cert, nil
+}
+
+// Watch implements WatchableSource by starting an fsnotify watcher on the parent directories of
+// CertFile and KeyFile, pushing a signal whenever either receives a WRITE, CREATE, or RENAME
+// event. Watching the parent directory rather than the file itself is required because certbot
+// (and similar tools) replace a certificate by renaming a new file over the old one, which
+// invalidates a watch held directly on the original file.
+//
+// The returned channel is closed when ctx is cancelled or Close is called.
+func (s *FileSource) Watch(ctx context.Context) (<-chan struct{}, error) {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, fmt.Errorf("could not create fsnotify watcher: %w", err)
+	}
+
+	dirs := map[string]struct{}{
+		filepath.Dir(s.CertFile): {},
+		filepath.Dir(s.KeyFile):  {},
+	}
+	for dir := range dirs {
+		if err := watcher.Add(dir); err != nil {
+			_ = watcher.Close()
+			return nil, fmt.Errorf("could not watch %s: %w", dir, err)
+		}
+	}
+
+	s.watcher = watcher
+
+	signals := make(chan struct{})
+	go s.watchLoop(ctx, watcher, signals)
+
+	return signals, nil
+}
+
+// watchLoop consumes fsnotify events for CertFile's and KeyFile's parent directories and
+// debounces them into signals on signals, closing it when ctx is cancelled or watcher is closed.
+func (s *FileSource) watchLoop(ctx context.Context, watcher *fsnotify.Watcher, signals chan<- struct{}) {
+	defer close(signals)
+
+	var debounce *time.Timer
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case event, ok := <-watcher.Events:
+			if !ok {
+				return
+			}
+			if event.Op&(fsnotify.Write|fsnotify.Create|fsnotify.Rename) == 0 {
+				continue
+			}
+			if debounce == nil {
+				debounce = time.NewTimer(fsNotifyDebounce)
+			} else {
+				if !debounce.Stop() {
+					<-debounce.C
+				}
+				debounce.Reset(fsNotifyDebounce)
+			}
+		case <-debounceC(debounce):
+			debounce = nil
+			select {
+			case signals <- struct{}{}:
+			case <-ctx.Done():
+				return
+			}
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return
+			}
+			logWarn(s.logger, "fsnotify watcher error", "error", err)
+		}
+	}
+}
+
+// debounceC returns t's channel, or nil if t is nil. Receiving from a nil channel blocks
+// forever, which is what we want in watchLoop's select when no debounce is currently pending.
+func debounceC(t *time.Timer) <-chan time.Time {
+	if t == nil {
+		return nil
+	}
+	return t.C
+}
+
+// Close stops the fsnotify watcher started by Watch, if any. It is safe to call even if Watch was
+// never called.
+func (s *FileSource) Close() error {
+	if s.watcher == nil {
+		return nil
+	}
+	return s.watcher.Close()
+}
+
+// An EnvSource loads a certificate and key from PEM-encoded contents stored directly in
+// environment variables, for deployments that inject certificates as secrets rather than files.
+type EnvSource struct {
+	CertEnv string
+	KeyEnv  string
+}
+
+// NewEnvSource creates an EnvSource reading PEM data from the certEnv, keyEnv environment
+// variables.
+func NewEnvSource(certEnv, keyEnv string) *EnvSource {
+	return &EnvSource{CertEnv: certEnv, KeyEnv: keyEnv}
+}
+
+// Load implements Source.
+func (s *EnvSource) Load(_ context.Context) (tls.Certificate, error) {
+	certPEM, ok := os.LookupEnv(s.CertEnv)
+	if !ok {
+		return tls.Certificate{}, fmt.Errorf("environment variable %s is not set", s.CertEnv)
+	}
+
+	keyPEM, ok := os.LookupEnv(s.KeyEnv)
+	if !ok {
+		return tls.Certificate{}, fmt.Errorf("environment variable %s is not set", s.KeyEnv)
+	}
+
+	cert, err := tls.X509KeyPair([]byte(certPEM), []byte(keyPEM))
+	if err != nil {
+		return tls.Certificate{}, fmt.Errorf("could not parse keypair: %w", err)
+	}
+
+	return cert, nil
+}
+
+// A MultiSource tries each of its Sources in order and returns the first one that loads
+// successfully, so a Reloader can fail over between e.g. a local cache and a remote secrets
+// store.
+type MultiSource struct {
+	Sources []Source
+}
+
+// NewMultiSource creates a MultiSource trying sources in the given order.
+func NewMultiSource(sources ...Source) *MultiSource {
+	return &MultiSource{Sources: sources}
+}
+
+// Load implements Source. It returns the first successful result, or, if every Source failed, an
+// error wrapping the last one encountered.
+func (s *MultiSource) Load(ctx context.Context) (tls.Certificate, error) {
+	if len(s.Sources) == 0 {
+		return tls.Certificate{}, fmt.Errorf("no sources configured")
+	}
+
+	var err error
+	for _, source := range s.Sources {
+		var cert tls.Certificate
+		cert, err = source.Load(ctx)
+		if err == nil {
+			return cert, nil
+		}
+	}
+
+	return tls.Certificate{}, fmt.Errorf("all sources failed, last error: %w", err)
+}
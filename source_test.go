@@ -0,0 +1,132 @@
+package certreloader
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"os"
+	"testing"
+	"time"
+)
+
+// fakeSource is a minimal Source used to exercise Reloader against backends other than
+// FileSource, mirroring what a third-party implementation (Vault, SPIRE, etc.) might return.
+type fakeSource struct {
+	cert tls.Certificate
+	err  error
+}
+
+func (s *fakeSource) Load(_ context.Context) (tls.Certificate, error) {
+	return s.cert, s.err
+}
+
+// TestMultiSourceFallsBackToNextSource verifies MultiSource tries sources in order and returns
+// the first successful result.
+func TestMultiSourceFallsBackToNextSource(t *testing.T) {
+	now := time.Now()
+	certPEM, keyPEM := generateTestCert(t, now.Add(-time.Hour), now.Add(time.Hour))
+	good, err := tls.X509KeyPair(certPEM, keyPEM)
+	if err != nil {
+		t.Fatalf("could not build keypair: %v", err)
+	}
+
+	source := NewMultiSource(
+		&fakeSource{err: errors.New("vault unreachable")},
+		&fakeSource{cert: good},
+	)
+
+	cert, err := source.Load(context.Background())
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if len(cert.Certificate) != len(good.Certificate) {
+		t.Fatalf("Load returned an unexpected certificate")
+	}
+}
+
+// TestMultiSourceAllFail verifies MultiSource returns an error wrapping the last failure when
+// every source fails.
+func TestMultiSourceAllFail(t *testing.T) {
+	source := NewMultiSource(
+		&fakeSource{err: errors.New("first failure")},
+		&fakeSource{err: errors.New("second failure")},
+	)
+
+	_, err := source.Load(context.Background())
+	if err == nil {
+		t.Fatal("expected an error when all sources fail")
+	}
+}
+
+// TestEnvSourceLoad verifies EnvSource reads and parses a keypair from environment variables.
+func TestEnvSourceLoad(t *testing.T) {
+	now := time.Now()
+	certPEM, keyPEM := generateTestCert(t, now.Add(-time.Hour), now.Add(time.Hour))
+
+	t.Setenv("CERTRELOADER_TEST_CERT", string(certPEM))
+	t.Setenv("CERTRELOADER_TEST_KEY", string(keyPEM))
+
+	source := NewEnvSource("CERTRELOADER_TEST_CERT", "CERTRELOADER_TEST_KEY")
+	if _, err := source.Load(context.Background()); err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+}
+
+// TestEnvSourceMissingVar verifies EnvSource returns an error when either environment variable is
+// unset, rather than attempting to parse an empty string.
+func TestEnvSourceMissingVar(t *testing.T) {
+	source := NewEnvSource("CERTRELOADER_TEST_MISSING_CERT", "CERTRELOADER_TEST_MISSING_KEY")
+	if _, err := source.Load(context.Background()); err == nil {
+		t.Fatal("expected an error for unset environment variables")
+	}
+}
+
+// TestReloaderFromFakeSourceRejectsEmptyCertificate verifies storeCert rejects a tls.Certificate
+// with no DER-encoded certificates rather than panicking on an unconditional slice index, since a
+// pluggable Source can return one by mistake.
+func TestReloaderFromFakeSourceRejectsEmptyCertificate(t *testing.T) {
+	_, err := NewReloaderFromSource(&fakeSource{cert: tls.Certificate{}})
+	if err == nil {
+		t.Fatal("expected an error for a certificate with no DER-encoded certificates")
+	}
+}
+
+// TestFileSourceIsWatchable verifies FileSource satisfies WatchableSource and that Watch delivers
+// a signal when the watched files change.
+func TestFileSourceIsWatchable(t *testing.T) {
+	now := time.Now()
+	certFile, keyFile := writeTestKeyPair(t, now.Add(-time.Hour), now.Add(time.Hour))
+
+	var source Source = NewFileSource(certFile, keyFile)
+	watchable, ok := source.(WatchableSource)
+	if !ok {
+		t.Fatal("*FileSource should implement WatchableSource")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	signals, err := watchable.Watch(ctx)
+	if err != nil {
+		t.Fatalf("Watch: %v", err)
+	}
+	defer func() {
+		if closer, ok := source.(interface{ Close() error }); ok {
+			_ = closer.Close()
+		}
+	}()
+
+	certPEM, keyPEM := generateTestCert(t, now, now.Add(2*time.Hour))
+	if err := os.WriteFile(certFile, certPEM, 0o600); err != nil {
+		t.Fatalf("could not rewrite cert file: %v", err)
+	}
+	if err := os.WriteFile(keyFile, keyPEM, 0o600); err != nil {
+		t.Fatalf("could not rewrite key file: %v", err)
+	}
+
+	select {
+	case <-signals:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Watch did not deliver a signal after the watched files changed")
+	}
+}